@@ -2,11 +2,13 @@
 package agentrpc
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/agentrpc/agentrpc/sdk-go/internal/client"
 	"github.com/agentrpc/agentrpc/sdk-go/internal/util"
@@ -22,18 +24,62 @@ const (
 
 // AgentRPC is the main client for interacting with the AgentRPC platform.
 type AgentRPC struct {
-	client      *client.Client
-	apiEndpoint string
-	apiSecret   string
-	machineID   string
-	clusterID   string
-	tools       *pollingAgent
+	client            *client.Client
+	apiEndpoint       string
+	apiSecret         string
+	machineID         string
+	clusterID         string
+	logger            Logger
+	middlewares       []Middleware
+	maxConcurrency    int
+	drainTimeout      time.Duration
+	pluginMaxRestarts int
+	transport         Transport
+	tools             *pollingAgent
 }
 
 type AgentRPCOptions struct {
 	APIEndpoint string
 	APISecret   string
 	MachineID   string
+
+	// Logger receives structured log events (poll failures, service
+	// lifecycle, unknown-function dispatches, ...) emitted by the SDK.
+	// Defaults to an slog logger writing to stderr. Pass NewNoopLogger()
+	// to silence the SDK entirely.
+	Logger Logger
+
+	// LogLevel sets the minimum severity Logger is called with. Defaults
+	// to LogLevelInfo.
+	LogLevel LogLevel
+
+	// Transport selects how job dispatches are received and results are
+	// submitted. Defaults to TransportHTTP. Set to TransportGRPC and call
+	// ServeGRPC instead of Listen to use the gRPC transport.
+	Transport Transport
+
+	// Middlewares wraps every tool invocation, regardless of transport,
+	// in the given order (first entry is outermost). Use Use to append
+	// more after construction.
+	Middlewares []Middleware
+
+	// MaxConcurrency bounds how many jobs may be executing at once across
+	// all tools. Defaults to DefaultMaxConcurrency. A per-tool ceiling can
+	// additionally be set via Tool.MaxConcurrency.
+	MaxConcurrency int
+
+	// DrainTimeout, if set, is how long Unlisten waits (via Drain) for
+	// in-flight jobs to finish persisting their results before cancelling
+	// the context.Context passed into handlers. Jobs whose handler is
+	// still running when it expires are reported back as a "rejection" so
+	// the control plane can reschedule them immediately; jobs still
+	// waiting for a worker pool slot are not rejected.
+	DrainTimeout time.Duration
+
+	// PluginMaxRestarts bounds how many times a PluginTool's process may be
+	// restarted after crashing before the plugin.Supervisor gives up and
+	// fails every subsequent call. Defaults to plugin.DefaultMaxRestarts.
+	PluginMaxRestarts int
 }
 
 // Initializes a new AgentRPC client.
@@ -57,6 +103,12 @@ func New(options AgentRPCOptions) (*AgentRPC, error) {
 		machineID = util.GenerateMachineID(8)
 	}
 
+	logger := options.Logger
+	if logger == nil {
+		logger = defaultLogger()
+	}
+	logger = newLeveledLogger(logger, options.LogLevel)
+
 	parts := strings.Split(options.APISecret, "_")
 	if len(parts) != 3 || parts[0] != "sk" {
 		return nil, fmt.Errorf("invalid API secret")
@@ -72,11 +124,17 @@ func New(options AgentRPCOptions) (*AgentRPC, error) {
 	}
 
 	rpc := &AgentRPC{
-		client:      client,
-		apiEndpoint: options.APIEndpoint,
-		apiSecret:   options.APISecret,
-		clusterID:   parts[1],
-		machineID:   machineID,
+		client:            client,
+		apiEndpoint:       options.APIEndpoint,
+		apiSecret:         options.APISecret,
+		clusterID:         parts[1],
+		machineID:         machineID,
+		logger:            logger,
+		middlewares:       options.Middlewares,
+		maxConcurrency:    options.MaxConcurrency,
+		drainTimeout:      options.DrainTimeout,
+		pluginMaxRestarts: options.PluginMaxRestarts,
+		transport:         options.Transport,
 	}
 
 	rpc.tools, err = rpc.createPollingAgent()
@@ -115,6 +173,12 @@ func (i *AgentRPC) Register(fn Tool) error {
 	return i.tools.Register(fn)
 }
 
+// RegisterPlugin registers a Tool backed by a separate process. See
+// PluginTool.
+func (i *AgentRPC) RegisterPlugin(pt PluginTool) error {
+	return i.tools.RegisterPlugin(pt)
+}
+
 func (i *AgentRPC) Listen() error {
 	return i.tools.Listen()
 }
@@ -123,11 +187,30 @@ func (i *AgentRPC) Unlisten() {
 	i.tools.Unlisten()
 }
 
+// Drain stops fetching new jobs and waits for every in-flight tool
+// invocation to finish persisting its result, or until ctx expires,
+// whichever comes first. Unlike Unlisten, it does not cancel the poll
+// loop, so Listen can resume fetching jobs once Drain returns (or its
+// caller may follow it with Unlisten to stop for good).
+func (i *AgentRPC) Drain(ctx context.Context) error {
+	return i.tools.Drain(ctx)
+}
+
+// Cancel cancels the context.Context passed into an in-flight tool
+// invocation for jobID, if one is still running. It reports whether a
+// matching job was found. Handlers that don't accept a context.Context
+// argument, or that have already returned, are unaffected.
+func (i *AgentRPC) Cancel(jobID string) bool {
+	return i.tools.cancelJob(jobID)
+}
+
 func (i *AgentRPC) createPollingAgent() (*pollingAgent, error) {
 
 	agent := &pollingAgent{
-		Tools:     make(map[string]Tool),
-		inferable: i, // Set the reference to the Inferable instance
+		Tools:       make(map[string]Tool),
+		inferable:   i, // Set the reference to the Inferable instance
+		logger:      i.logger,
+		middlewares: i.middlewares,
 	}
 	return agent, nil
 }
@@ -252,8 +335,9 @@ func (i *AgentRPC) registerMachine(s *pollingAgent) (string, error) {
 		Body:    string(jsonPayload),
 	}
 
-	responseData, _, err, _ := i.fetchData(options)
+	responseData, _, err, status := i.fetchData(options)
 	if err != nil {
+		i.logger.Error("failed to register machine", "cluster_id", i.clusterID, "machine_id", i.machineID, "http_status", status, "error", err)
 		return "", fmt.Errorf("failed to register machine: %v", err)
 	}
 
@@ -267,5 +351,6 @@ func (i *AgentRPC) registerMachine(s *pollingAgent) (string, error) {
 		return "", fmt.Errorf("failed to parse registration response: %v", err)
 	}
 
+	i.logger.Debug("registered machine", "cluster_id", response.ClusterId, "machine_id", i.machineID)
 	return response.ClusterId, nil
 }
@@ -0,0 +1,59 @@
+package agentrpc
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc"
+
+	transportgrpc "github.com/agentrpc/agentrpc/sdk-go/transport/grpc"
+)
+
+// Transport selects how a machine receives job dispatches and submits
+// results.
+type Transport int
+
+const (
+	// TransportHTTP (the default) long-polls the jobs endpoint, as
+	// implemented by pollingAgent.poll.
+	TransportHTTP Transport = iota
+	// TransportGRPC exposes the same registered Tools over gRPC so
+	// agentrpc can be embedded next to an existing gRPC service and
+	// consumed over HTTP/2 instead of long-polling.
+	TransportGRPC
+)
+
+// grpcDispatcherAdapter adapts a pollingAgent to transportgrpc.Dispatcher
+// without the transport/grpc package importing the agentrpc package,
+// avoiding an import cycle while sharing the same reflect-based dispatch
+// code as the HTTP transport.
+type grpcDispatcherAdapter struct {
+	agent *pollingAgent
+}
+
+func (a grpcDispatcherAdapter) Tools() []string {
+	return a.agent.toolNames()
+}
+
+func (a grpcDispatcherAdapter) Dispatch(ctx context.Context, jobID, function string, input []byte) ([]byte, string, int64) {
+	return a.agent.dispatchJSON(ctx, jobID, function, input)
+}
+
+// ServeGRPC starts the gRPC transport on addr, blocking until the server
+// stops. Use it instead of Listen() when AgentRPCOptions.Transport is
+// TransportGRPC.
+func (i *AgentRPC) ServeGRPC(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", addr, err)
+	}
+
+	server := transportgrpc.NewServer(grpcDispatcherAdapter{agent: i.tools})
+
+	grpcServer := grpc.NewServer()
+	server.Register(grpcServer)
+
+	i.logger.Info("starting gRPC transport", "addr", addr, "cluster_id", i.clusterID, "machine_id", i.machineID)
+	return grpcServer.Serve(lis)
+}
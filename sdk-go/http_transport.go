@@ -0,0 +1,119 @@
+package agentrpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ServeHTTP starts a local HTTP surface exposing every registered Tool as
+// POST /tools/{name}, plus GET /tools and GET /openapi.json describing
+// them, on addr. It blocks until the server stops or returns an error.
+//
+// Unlike Listen, ServeHTTP never talks to the control plane: requests are
+// dispatched directly through dispatchJSON, the same reflect-based path
+// the poll loop and the gRPC transport use. This makes a registered Tool
+// reachable from curl, a local agent, or a test without a cluster or API
+// secret, without reaching into the unexported callFunc as TestCallFunc
+// does.
+func (i *AgentRPC) ServeHTTP(addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tools/", i.handleToolCall)
+	mux.HandleFunc("/tools", i.handleListTools)
+	mux.HandleFunc("/openapi.json", i.handleOpenAPI)
+
+	i.logger.Info("starting local HTTP surface", "addr", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+func (i *AgentRPC) handleToolCall(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(r.URL.Path, "/tools/")
+	if _, ok := i.tools.Tools[name]; !ok {
+		http.Error(w, "unknown tool: "+name, http.StatusNotFound)
+		return
+	}
+
+	input, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	jobID, err := randomJobID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, resultType, _ := i.tools.dispatchJSON(r.Context(), jobID, name, input)
+
+	w.Header().Set("Content-Type", "application/json")
+	if resultType == "rejection" {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}
+	w.Write(result)
+}
+
+func (i *AgentRPC) handleListTools(w http.ResponseWriter, r *http.Request) {
+	names := i.tools.toolNames()
+	sort.Strings(names)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(names)
+}
+
+// handleOpenAPI emits a minimal OpenAPI 3.0 document describing each
+// registered Tool as a POST /tools/{name} operation, reusing the JSON
+// Schema Register already produced rather than deriving one separately.
+func (i *AgentRPC) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	paths := make(map[string]interface{})
+	for name, fn := range i.tools.Tools {
+		paths["/tools/"+name] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"summary": fn.Description,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content": map[string]interface{}{
+						"application/json": map[string]interface{}{
+							"schema": fn.schema,
+						},
+					},
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{"description": "tool result"},
+				},
+			},
+		}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "AgentRPC local tools",
+			"version": Version,
+		},
+		"paths": paths,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(doc)
+}
+
+// randomJobID generates a job ID for a ServeHTTP request, which has no
+// control-plane-issued one to use instead.
+func randomJobID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "local-" + hex.EncodeToString(b), nil
+}
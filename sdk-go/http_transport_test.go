@@ -0,0 +1,49 @@
+package agentrpc
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandleToolCall(t *testing.T) {
+	i, _ := New(AgentRPCOptions{
+		APIEndpoint: DefaultAPIEndpoint,
+		APISecret:   "sk_secret_123",
+	})
+
+	type TestInput struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}
+
+	err := i.Register(Tool{
+		Handler: func(input TestInput) int { return input.A + input.B },
+		Name:    "Add",
+	})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, "/tools/Add", strings.NewReader(`{"a":2,"b":3}`))
+	rec := httptest.NewRecorder()
+	i.handleToolCall(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "5", strings.TrimSpace(rec.Body.String()))
+}
+
+func TestHandleToolCallUnknownTool(t *testing.T) {
+	i, _ := New(AgentRPCOptions{
+		APIEndpoint: DefaultAPIEndpoint,
+		APISecret:   "sk_secret_123",
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/tools/Missing", nil)
+	rec := httptest.NewRecorder()
+	i.handleToolCall(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,126 @@
+package agentrpc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// deadlineTimer implements a resettable deadline on top of a channel that
+// closes when the deadline passes, following the pattern used by
+// gVisor/netstack's deadlineTimer: a zero deadline leaves the channel
+// open forever, and setting a deadline that has already passed closes it
+// immediately. Because a closed channel can't be "reopened", a new
+// deadline set after the old one fired swaps in a fresh channel so
+// callers selecting on done() always see the current deadline.
+type deadlineTimer struct {
+	mu       sync.Mutex
+	cancelCh chan struct{}
+	timer    *time.Timer
+}
+
+func (d *deadlineTimer) init() {
+	d.cancelCh = make(chan struct{})
+}
+
+// done returns the channel that closes when the current deadline passes.
+// It never closes if no deadline has been set.
+func (d *deadlineTimer) done() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.cancelCh
+}
+
+// setDeadline arms (or disarms, for a zero Time) the timer.
+func (d *deadlineTimer) setDeadline(deadline time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+
+	// If the previous deadline already fired, cancelCh is closed; swap in
+	// a fresh one so a later deadline can still be observed.
+	select {
+	case <-d.cancelCh:
+		d.cancelCh = make(chan struct{})
+	default:
+	}
+
+	if deadline.IsZero() {
+		return
+	}
+
+	dur := time.Until(deadline)
+	if dur <= 0 {
+		close(d.cancelCh)
+		return
+	}
+
+	ch := d.cancelCh
+	d.timer = time.AfterFunc(dur, func() { close(ch) })
+}
+
+// beginJob derives a cancellable context for a single in-flight job from
+// s.ctx (so Unlisten/poll-loop shutdown cancels every in-flight job for
+// free), registers its CancelFunc so AgentRPC.Cancel can reach it, and
+// arms a deadline if timeout is non-zero.
+//
+// The returned finish func must be called exactly once when the job
+// completes; it reports whether the job was cancelled because its
+// deadline passed (as opposed to being cancelled some other way, or not
+// being cancelled at all).
+func (s *pollingAgent) beginJob(jobID string, timeout time.Duration) (ctx context.Context, finish func() bool) {
+	ctx, cancel := context.WithCancel(s.ctx)
+
+	dt := &deadlineTimer{}
+	dt.init()
+	if timeout > 0 {
+		dt.setDeadline(time.Now().Add(timeout))
+	}
+
+	s.inFlightMu.Lock()
+	s.inFlight[jobID] = cancel
+	s.inFlightMu.Unlock()
+
+	stop := make(chan struct{})
+	var timedOut atomic.Bool
+	go func() {
+		select {
+		case <-dt.done():
+			timedOut.Store(true)
+			cancel()
+		case <-stop:
+		}
+	}()
+
+	return ctx, func() bool {
+		close(stop)
+		dt.setDeadline(time.Time{})
+
+		s.inFlightMu.Lock()
+		delete(s.inFlight, jobID)
+		s.inFlightMu.Unlock()
+
+		cancel()
+		return timedOut.Load()
+	}
+}
+
+// cancelJob cancels an in-flight job's context, if it's still running.
+// It reports whether a matching job was found.
+func (s *pollingAgent) cancelJob(jobID string) bool {
+	s.inFlightMu.Lock()
+	cancel, ok := s.inFlight[jobID]
+	s.inFlightMu.Unlock()
+
+	if !ok {
+		return false
+	}
+
+	cancel()
+	return true
+}
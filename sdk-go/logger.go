@@ -0,0 +1,110 @@
+package agentrpc
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Logger is the structured logging interface used throughout the SDK.
+//
+// Implementations should treat kv as alternating key/value pairs, matching
+// the convention used by log/slog and most structured logging libraries
+// (zap's SugaredLogger, zerolog's Event.Fields, etc). This makes it
+// straightforward to adapt an existing application logger without wrapping
+// every call site.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+}
+
+// LogLevel is the minimum severity AgentRPCOptions.Logger will emit. Its
+// zero value is LogLevelInfo, so per-poll Debug noise (saturated worker
+// pool, retry-after headers, ...) is silent by default while lifecycle
+// events and failures still surface.
+type LogLevel int
+
+const (
+	LogLevelDebug LogLevel = iota - 1
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+)
+
+// leveledLogger wraps a Logger and drops calls below the configured
+// LogLevel, so callers can quiet the SDK down without needing their own
+// Logger implementation to track a level.
+type leveledLogger struct {
+	next  Logger
+	level LogLevel
+}
+
+func newLeveledLogger(next Logger, level LogLevel) Logger {
+	return &leveledLogger{next: next, level: level}
+}
+
+func (l *leveledLogger) Debug(msg string, kv ...any) {
+	if l.level <= LogLevelDebug {
+		l.next.Debug(msg, kv...)
+	}
+}
+
+func (l *leveledLogger) Info(msg string, kv ...any) {
+	if l.level <= LogLevelInfo {
+		l.next.Info(msg, kv...)
+	}
+}
+
+func (l *leveledLogger) Warn(msg string, kv ...any) {
+	if l.level <= LogLevelWarn {
+		l.next.Warn(msg, kv...)
+	}
+}
+
+func (l *leveledLogger) Error(msg string, kv ...any) {
+	if l.level <= LogLevelError {
+		l.next.Error(msg, kv...)
+	}
+}
+
+// slogLogger adapts a *slog.Logger to the Logger interface.
+type slogLogger struct {
+	l *slog.Logger
+}
+
+// NewSlogLogger returns a Logger backed by the standard library's log/slog
+// package. If l is nil, slog.Default() is used.
+func NewSlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		l = slog.Default()
+	}
+	return &slogLogger{l: l}
+}
+
+func (s *slogLogger) Debug(msg string, kv ...any) { s.l.Debug(msg, kv...) }
+func (s *slogLogger) Info(msg string, kv ...any)  { s.l.Info(msg, kv...) }
+func (s *slogLogger) Warn(msg string, kv ...any)  { s.l.Warn(msg, kv...) }
+func (s *slogLogger) Error(msg string, kv ...any) { s.l.Error(msg, kv...) }
+
+// noopLogger discards every log line. It's not used unless passed in
+// explicitly via NewNoopLogger, e.g. by tests or callers who don't want the
+// SDK to log anything on their behalf.
+type noopLogger struct{}
+
+// NewNoopLogger returns a Logger that discards everything written to it.
+func NewNoopLogger() Logger {
+	return noopLogger{}
+}
+
+func (noopLogger) Debug(string, ...any) {}
+func (noopLogger) Info(string, ...any)  {}
+func (noopLogger) Warn(string, ...any)  {}
+func (noopLogger) Error(string, ...any) {}
+
+// defaultLogger returns the Logger the SDK falls back to when
+// AgentRPCOptions.Logger is not set: an slog logger writing to stderr at
+// info level.
+func defaultLogger() Logger {
+	return NewSlogLogger(slog.New(slog.NewTextHandler(os.Stderr, nil)))
+}
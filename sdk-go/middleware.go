@@ -0,0 +1,188 @@
+package agentrpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"time"
+)
+
+// Call describes a single tool invocation as it flows through the
+// middleware chain. It mirrors the fields a transport already has on hand
+// (pollingAgent.dispatchJSON, the gRPC transport's JobDispatch) so
+// middlewares work the same regardless of which transport dispatched the
+// job.
+type Call struct {
+	JobID     string
+	Tool      string
+	ClusterID string
+	MachineID string
+	Input     []byte
+}
+
+// CallOutcome is what a Handler produces for a Call.
+type CallOutcome struct {
+	Result     []byte
+	ResultType string
+}
+
+// Handler executes a Call. The innermost Handler in a chain is always the
+// reflect-based tool dispatch (pollingAgent.baseHandler); everything else
+// is a Middleware wrapping it.
+type Handler func(ctx context.Context, call *Call) (*CallOutcome, error)
+
+// Middleware wraps a Handler with additional behavior, in the same shape
+// as a gRPC unary interceptor: call next() to continue the chain, or
+// short-circuit by returning without calling it.
+type Middleware func(next Handler) Handler
+
+// chain composes middlewares around base, in the order given: the first
+// middleware in the slice is outermost (runs first, sees the raw Call;
+// returns last, sees the final CallOutcome).
+func chain(base Handler, middlewares []Middleware) Handler {
+	h := base
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		h = middlewares[i](h)
+	}
+	return h
+}
+
+// Use appends middlewares to the chain every tool invocation runs through,
+// regardless of transport. Must be called before Listen/ServeGRPC.
+func (i *AgentRPC) Use(middlewares ...Middleware) {
+	i.middlewares = append(i.middlewares, middlewares...)
+	i.tools.middlewares = i.middlewares
+}
+
+// LoggingMiddleware logs the start and outcome of every call using logger,
+// carrying the same structured fields as the built-in poll-loop logging.
+func LoggingMiddleware(logger Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*CallOutcome, error) {
+			logger.Debug("dispatching tool call", "job_id", call.JobID, "tool", call.Tool, "cluster_id", call.ClusterID)
+			outcome, err := next(ctx, call)
+			if err != nil {
+				logger.Error("tool call failed", "job_id", call.JobID, "tool", call.Tool, "error", err)
+				return outcome, err
+			}
+			logger.Info("tool call completed", "job_id", call.JobID, "tool", call.Tool, "result_type", outcome.ResultType)
+			return outcome, err
+		}
+	}
+}
+
+// RecoveryMiddleware turns a panic inside an inner Handler (ultimately a
+// user Tool.Handler) into a "rejection" CallOutcome carrying the captured
+// stack, instead of crashing the poll loop.
+func RecoveryMiddleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (outcome *CallOutcome, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					result, _ := json.Marshal(fmt.Sprintf("panic: %v\n%s", r, debug.Stack()))
+					outcome = &CallOutcome{
+						Result:     result,
+						ResultType: "rejection",
+					}
+					err = nil
+				}
+			}()
+			return next(ctx, call)
+		}
+	}
+}
+
+// TimeoutMiddleware derives a context.WithTimeout(d) for every call and
+// returns a "rejection" outcome if the inner Handler doesn't finish in
+// time. The inner Handler keeps running in the background; it is up to
+// the Tool.Handler to observe ctx.Done() if it wants to stop early.
+func TimeoutMiddleware(d time.Duration) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*CallOutcome, error) {
+			ctx, cancel := context.WithTimeout(ctx, d)
+			defer cancel()
+
+			type result struct {
+				outcome *CallOutcome
+				err     error
+			}
+			done := make(chan result, 1)
+			go func() {
+				outcome, err := next(ctx, call)
+				done <- result{outcome, err}
+			}()
+
+			select {
+			case r := <-done:
+				return r.outcome, r.err
+			case <-ctx.Done():
+				result, _ := json.Marshal(fmt.Sprintf("tool %q timed out after %s", call.Tool, d))
+				return &CallOutcome{
+					Result:     result,
+					ResultType: "rejection",
+				}, nil
+			}
+		}
+	}
+}
+
+// MetricsSink receives per-call measurements. It's intentionally minimal
+// so callers can adapt it to Prometheus (a latency histogram, an in-flight
+// gauge, an error counter partitioned by resultType) or OpenTelemetry
+// metrics without this package depending on either.
+type MetricsSink interface {
+	ObserveLatency(tool string, d time.Duration)
+	IncInFlight(tool string, delta int)
+	IncResult(tool, resultType string)
+}
+
+// MetricsMiddleware records latency, in-flight count, and result-type
+// counts for every call to sink.
+func MetricsMiddleware(sink MetricsSink) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*CallOutcome, error) {
+			sink.IncInFlight(call.Tool, 1)
+			defer sink.IncInFlight(call.Tool, -1)
+
+			start := time.Now()
+			outcome, err := next(ctx, call)
+			sink.ObserveLatency(call.Tool, time.Since(start))
+
+			resultType := "error"
+			if outcome != nil {
+				resultType = outcome.ResultType
+			}
+			sink.IncResult(call.Tool, resultType)
+
+			return outcome, err
+		}
+	}
+}
+
+// Tracer starts a span for a Call, using runCtx as the parent traceparent
+// so a tool invocation's span can nest under whatever the caller considers
+// its parent. Call itself carries no such field; runCtx is whatever
+// runContextOf (below) extracts, and may be nil.
+type Tracer interface {
+	StartSpan(ctx context.Context, tool string, runCtx interface{}) (context.Context, func())
+}
+
+// TracingMiddleware starts a span around every call via tracer. runContextOf
+// extracts the parent identifier tracer.StartSpan should nest under from
+// call; pass a func that always returns nil (or nil itself) if the caller
+// has no such concept and spans should simply nest under ctx's existing
+// span, if any.
+func TracingMiddleware(tracer Tracer, runContextOf func(call *Call) interface{}) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, call *Call) (*CallOutcome, error) {
+			var runCtx interface{}
+			if runContextOf != nil {
+				runCtx = runContextOf(call)
+			}
+			ctx, end := tracer.StartSpan(ctx, call.Tool, runCtx)
+			defer end()
+			return next(ctx, call)
+		}
+	}
+}
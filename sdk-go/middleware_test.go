@@ -0,0 +1,123 @@
+package agentrpc
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainOrdering(t *testing.T) {
+	var order []string
+
+	mw := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, call *Call) (*CallOutcome, error) {
+				order = append(order, name+":before")
+				outcome, err := next(ctx, call)
+				order = append(order, name+":after")
+				return outcome, err
+			}
+		}
+	}
+
+	base := func(ctx context.Context, call *Call) (*CallOutcome, error) {
+		order = append(order, "base")
+		return &CallOutcome{ResultType: "resolution"}, nil
+	}
+
+	h := chain(base, []Middleware{mw("outer"), mw("inner")})
+	_, err := h(context.Background(), &Call{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"outer:before", "inner:before", "base", "inner:after", "outer:after"}, order)
+}
+
+func TestRecoveryMiddlewareConvertsPanicToRejection(t *testing.T) {
+	h := RecoveryMiddleware()(func(ctx context.Context, call *Call) (*CallOutcome, error) {
+		panic("boom")
+	})
+
+	outcome, err := h(context.Background(), &Call{Tool: "PanicTool"})
+	require.NoError(t, err)
+	require.NotNil(t, outcome)
+	assert.Equal(t, "rejection", outcome.ResultType)
+	assert.Contains(t, string(outcome.Result), "panic: boom")
+}
+
+func TestTimeoutMiddlewareRejectsSlowHandler(t *testing.T) {
+	h := TimeoutMiddleware(10 * time.Millisecond)(func(ctx context.Context, call *Call) (*CallOutcome, error) {
+		<-ctx.Done()
+		return &CallOutcome{ResultType: "resolution"}, nil
+	})
+
+	outcome, err := h(context.Background(), &Call{Tool: "SlowTool"})
+	require.NoError(t, err)
+	require.NotNil(t, outcome)
+	assert.Equal(t, "rejection", outcome.ResultType)
+	assert.Contains(t, string(outcome.Result), "SlowTool")
+}
+
+type fakeMetricsSink struct {
+	inFlightDelta int
+	results       []string
+}
+
+func (s *fakeMetricsSink) ObserveLatency(tool string, d time.Duration) {}
+func (s *fakeMetricsSink) IncInFlight(tool string, delta int)          { s.inFlightDelta += delta }
+func (s *fakeMetricsSink) IncResult(tool, resultType string) {
+	s.results = append(s.results, resultType)
+}
+
+func TestMetricsMiddlewareRecordsOutcome(t *testing.T) {
+	sink := &fakeMetricsSink{}
+	h := MetricsMiddleware(sink)(func(ctx context.Context, call *Call) (*CallOutcome, error) {
+		return &CallOutcome{ResultType: "resolution"}, nil
+	})
+
+	_, err := h(context.Background(), &Call{Tool: "MetricsTool"})
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, sink.inFlightDelta, "IncInFlight(+1) must be balanced by IncInFlight(-1) once the call returns")
+	assert.Equal(t, []string{"resolution"}, sink.results)
+}
+
+type fakeTracer struct {
+	startedWith interface{}
+	ended       bool
+}
+
+func (t *fakeTracer) StartSpan(ctx context.Context, tool string, runCtx interface{}) (context.Context, func()) {
+	t.startedWith = runCtx
+	return ctx, func() { t.ended = true }
+}
+
+func TestTracingMiddlewarePassesExtractedRunContext(t *testing.T) {
+	tracer := &fakeTracer{}
+	h := TracingMiddleware(tracer, func(call *Call) interface{} {
+		return fmt.Sprintf("run-for-%s", call.JobID)
+	})(func(ctx context.Context, call *Call) (*CallOutcome, error) {
+		return &CallOutcome{ResultType: "resolution"}, nil
+	})
+
+	_, err := h(context.Background(), &Call{JobID: "job-1"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "run-for-job-1", tracer.startedWith)
+	assert.True(t, tracer.ended)
+}
+
+func TestTracingMiddlewareWithNoRunContextExtractor(t *testing.T) {
+	tracer := &fakeTracer{}
+	h := TracingMiddleware(tracer, nil)(func(ctx context.Context, call *Call) (*CallOutcome, error) {
+		return &CallOutcome{ResultType: "resolution"}, nil
+	})
+
+	_, err := h(context.Background(), &Call{JobID: "job-1"})
+	require.NoError(t, err)
+
+	assert.Nil(t, tracer.startedWith)
+}
@@ -0,0 +1,18 @@
+// Package plugin lets an AgentRPC tool be implemented as a separate
+// process, using hashicorp/go-plugin for transport. A plugin binary
+// implements the Tool interface and calls Serve to speak the ToolPlugin
+// gRPC protocol over the handshake in Handshake; the host side (the
+// agentrpc package's PluginTool, via this package's Supervisor) launches that binary,
+// negotiates the handshake, and forwards calls to it over gRPC.
+//
+// toolplugin.proto is the source of truth for the wire types, but the
+// toolpluginpb package committed alongside it is hand-written rather than
+// protoc-generated: this repo has no protoc/protoc-gen-go-grpc in its
+// build pipeline. toolpluginpb/codec.go registers a JSON codec under
+// grpc's "proto" name so the client/server pair works without the
+// protobuf runtime. Once protoc is available, regenerate properly with:
+//
+//	protoc --go_out=. --go-grpc_out=. sdk-go/plugin/toolplugin.proto
+//
+// and delete toolpluginpb/codec.go.
+package plugin
@@ -0,0 +1,97 @@
+package plugin
+
+import (
+	"context"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+
+	toolpluginpb "github.com/agentrpc/agentrpc/sdk-go/plugin/toolpluginpb"
+)
+
+// Handshake is the go-plugin handshake every AgentRPC tool plugin must
+// negotiate before the host will talk to it. Bump ProtocolVersion when
+// toolplugin.proto changes incompatibly; child processes built against an
+// older version will then fail the handshake loudly instead of exchanging
+// bytes neither side can parse.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  1,
+	MagicCookieKey:   "AGENTRPC_PLUGIN",
+	MagicCookieValue: "tool",
+}
+
+// PluginMap is the go-plugin plugin set shared by every AgentRPC tool
+// plugin host and client; "tool" is the only kind today.
+var PluginMap = map[string]goplugin.Plugin{
+	"tool": &GRPCPlugin{},
+}
+
+// Tool is what a plugin binary implements: invoke the tool against a
+// JSON-encoded input and return a JSON-encoded output. It's the
+// out-of-process analogue of Tool.Handler in the agentrpc package.
+type Tool interface {
+	Call(ctx context.Context, jobID string, input []byte) ([]byte, error)
+}
+
+// GRPCPlugin adapts a Tool to go-plugin's GRPCPlugin interface, so it can
+// be served by Serve (plugin-side) or dispensed as a client (host-side,
+// via Supervisor).
+type GRPCPlugin struct {
+	goplugin.Plugin
+
+	// Impl is set plugin-side before calling Serve.
+	Impl Tool
+}
+
+func (p *GRPCPlugin) GRPCServer(_ *goplugin.GRPCBroker, s *grpc.Server) error {
+	toolpluginpb.RegisterToolPluginServer(s, &grpcServer{impl: p.Impl})
+	return nil
+}
+
+func (p *GRPCPlugin) GRPCClient(_ context.Context, _ *goplugin.GRPCBroker, conn *grpc.ClientConn) (interface{}, error) {
+	return &grpcClient{client: toolpluginpb.NewToolPluginClient(conn)}, nil
+}
+
+// Serve runs impl as a plugin process, blocking until the host
+// disconnects. Call it from a plugin binary's main:
+//
+//	func main() {
+//	    plugin.Serve(myTool{})
+//	}
+func Serve(impl Tool) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins: map[string]goplugin.Plugin{
+			"tool": &GRPCPlugin{Impl: impl},
+		},
+		GRPCServer: goplugin.DefaultGRPCServer,
+	})
+}
+
+// grpcServer adapts a Tool to the generated ToolPluginServer interface.
+type grpcServer struct {
+	toolpluginpb.UnimplementedToolPluginServer
+	impl Tool
+}
+
+func (s *grpcServer) Call(ctx context.Context, req *toolpluginpb.CallRequest) (*toolpluginpb.CallResponse, error) {
+	output, err := s.impl.Call(ctx, req.JobId, req.Input)
+	if err != nil {
+		return nil, err
+	}
+	return &toolpluginpb.CallResponse{Output: output}, nil
+}
+
+// grpcClient adapts the generated ToolPluginClient to the Tool interface,
+// so Supervisor can call it the same way it would an in-process Tool.
+type grpcClient struct {
+	client toolpluginpb.ToolPluginClient
+}
+
+func (c *grpcClient) Call(ctx context.Context, jobID string, input []byte) ([]byte, error) {
+	resp, err := c.client.Call(ctx, &toolpluginpb.CallRequest{JobId: jobID, Input: input})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Output, nil
+}
@@ -0,0 +1,150 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sync"
+	"time"
+
+	goplugin "github.com/hashicorp/go-plugin"
+)
+
+// DefaultMaxRestarts is how many times a Supervisor will relaunch a
+// crashed plugin process before giving up and failing every subsequent
+// Call outright.
+const DefaultMaxRestarts = 5
+
+// baseBackoff is the delay before the first restart attempt; it doubles
+// with each further restart, capped at maxBackoff. The restart count is
+// never reset, so it covers every restart over the Supervisor's lifetime.
+const (
+	baseBackoff = 250 * time.Millisecond
+	maxBackoff  = 30 * time.Second
+)
+
+// Supervisor launches a tool plugin process and keeps it running,
+// restarting it with exponential backoff if it crashes. A crash never
+// propagates past Call as anything other than an error for the one
+// outstanding invocation; the caller (agentrpc's handleMessage) is
+// expected to report that as a "rejection" and let the job be retried.
+type Supervisor struct {
+	cmd         []string
+	maxRestarts int
+
+	mu       sync.Mutex
+	client   *goplugin.Client
+	tool     Tool
+	restarts int
+}
+
+// NewSupervisor returns a Supervisor for a plugin launched as cmd[0] with
+// arguments cmd[1:]. maxRestarts <= 0 uses DefaultMaxRestarts.
+func NewSupervisor(cmd []string, maxRestarts int) *Supervisor {
+	if maxRestarts <= 0 {
+		maxRestarts = DefaultMaxRestarts
+	}
+	return &Supervisor{cmd: cmd, maxRestarts: maxRestarts}
+}
+
+// Call invokes the plugin's Tool.Call, starting the child process on
+// first use. If the client connection has died (the process crashed or
+// exited) before this call, Call restarts it, subject to maxRestarts and
+// exponential backoff, before invoking it. A call that fails is not
+// retried: the child is killed so the next Call restarts it, and the
+// error is returned.
+func (s *Supervisor) Call(ctx context.Context, jobID string, input []byte) ([]byte, error) {
+	tool, err := s.ensureStarted()
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := tool.Call(ctx, jobID, input)
+	if err == nil {
+		return output, nil
+	}
+
+	// The RPC itself failing (as opposed to ctx being cancelled) usually
+	// means the child died mid-call; restart it so the *next* job isn't
+	// doomed too, but still report this one as failed.
+	if ctx.Err() == nil {
+		s.mu.Lock()
+		s.killLocked()
+		s.mu.Unlock()
+	}
+	return nil, fmt.Errorf("plugin call failed: %w", err)
+}
+
+// Close terminates the plugin process, if running.
+func (s *Supervisor) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.killLocked()
+}
+
+func (s *Supervisor) ensureStarted() (Tool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client != nil && !s.client.Exited() {
+		return s.tool, nil
+	}
+
+	if s.restarts > 0 {
+		if s.restarts > s.maxRestarts {
+			return nil, fmt.Errorf("plugin %v exceeded %d restarts, giving up", s.cmd, s.maxRestarts)
+		}
+		time.Sleep(backoffFor(s.restarts))
+	}
+	s.restarts++
+
+	client := goplugin.NewClient(&goplugin.ClientConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         PluginMap,
+		Cmd:             exec.Command(s.cmd[0], s.cmd[1:]...),
+		AllowedProtocols: []goplugin.Protocol{
+			goplugin.ProtocolGRPC,
+		},
+	})
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("failed to start plugin %v: %w", s.cmd, err)
+	}
+
+	raw, err := rpcClient.Dispense("tool")
+	if err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("failed to dispense plugin %v: %w", s.cmd, err)
+	}
+
+	tool, ok := raw.(Tool)
+	if !ok {
+		client.Kill()
+		return nil, fmt.Errorf("plugin %v did not implement Tool", s.cmd)
+	}
+
+	s.client = client
+	s.tool = tool
+	return tool, nil
+}
+
+func (s *Supervisor) killLocked() {
+	if s.client != nil {
+		s.client.Kill()
+		s.client = nil
+		s.tool = nil
+	}
+}
+
+func backoffFor(attempt int) time.Duration {
+	d := baseBackoff
+	for i := 1; i < attempt && d < maxBackoff; i++ {
+		d *= 2
+	}
+	if d > maxBackoff {
+		d = maxBackoff
+	}
+	return d
+}
@@ -0,0 +1,16 @@
+// Package toolpluginpb contains the Go bindings for toolplugin.proto: the
+// message types and the ToolPlugin client/server pair. These are
+// hand-written rather than protoc-generated; see this plugin package's
+// doc.go for why.
+package toolpluginpb
+
+// CallRequest is the host's invocation of a plugin's Tool.Call.
+type CallRequest struct {
+	JobId string `json:"job_id"`
+	Input []byte `json:"input"`
+}
+
+// CallResponse is the plugin's response to a CallRequest.
+type CallResponse struct {
+	Output []byte `json:"output"`
+}
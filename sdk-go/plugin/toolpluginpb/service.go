@@ -0,0 +1,79 @@
+package toolpluginpb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ToolPluginClient is the client API for the ToolPlugin service.
+type ToolPluginClient interface {
+	Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error)
+}
+
+type toolPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewToolPluginClient returns a ToolPluginClient using cc.
+func NewToolPluginClient(cc grpc.ClientConnInterface) ToolPluginClient {
+	return &toolPluginClient{cc}
+}
+
+func (c *toolPluginClient) Call(ctx context.Context, in *CallRequest, opts ...grpc.CallOption) (*CallResponse, error) {
+	out := new(CallResponse)
+	if err := c.cc.Invoke(ctx, "/agentrpc.plugin.ToolPlugin/Call", in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ToolPluginServer is the server API for the ToolPlugin service.
+type ToolPluginServer interface {
+	Call(context.Context, *CallRequest) (*CallResponse, error)
+}
+
+// UnimplementedToolPluginServer can be embedded for forward compatibility.
+type UnimplementedToolPluginServer struct{}
+
+func (UnimplementedToolPluginServer) Call(context.Context, *CallRequest) (*CallResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Call not implemented")
+}
+
+// RegisterToolPluginServer registers srv on s.
+func RegisterToolPluginServer(s grpc.ServiceRegistrar, srv ToolPluginServer) {
+	s.RegisterService(&toolPluginServiceDesc, srv)
+}
+
+func _ToolPlugin_Call_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CallRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToolPluginServer).Call(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/agentrpc.plugin.ToolPlugin/Call",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToolPluginServer).Call(ctx, req.(*CallRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var toolPluginServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agentrpc.plugin.ToolPlugin",
+	HandlerType: (*ToolPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Call",
+			Handler:    _ToolPlugin_Call_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "sdk-go/plugin/toolplugin.proto",
+}
@@ -4,15 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"reflect"
 	"strconv"
-	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/invopop/jsonschema"
 
 	"github.com/agentrpc/agentrpc/sdk-go/internal/client"
+	"github.com/agentrpc/agentrpc/sdk-go/plugin"
 )
 
 const (
@@ -20,26 +21,94 @@ const (
 	DefaultRetryAfter          = 0
 )
 
+// contextType is the reflect.Type of context.Context, used by Register and
+// invokeTool to detect handlers taking a leading context.Context argument.
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
 type Tool struct {
 	Name        string
 	Description string
 	schema      interface{}
 	Config      interface{}
 	Handler     interface{}
+
+	// MaxConcurrency caps how many invocations of this tool may run at
+	// once, on top of AgentRPCOptions.MaxConcurrency. Zero means the
+	// global cap is the only limit.
+	MaxConcurrency int
+
+	// plugin is set instead of Handler for tools registered via
+	// RegisterPlugin: invokeTool dispatches to it over the plugin's gRPC
+	// connection rather than calling Handler through reflection.
+	plugin *plugin.Supervisor
+}
+
+// PluginTool registers a tool backed by a separate process instead of an
+// in-process Go function, launched and supervised via sdk-go/plugin. This
+// lets a tool be written in another language, sandboxes a crashy handler
+// (the supervisor restarts the child rather than taking the polling agent
+// down with it), and allows swapping the tool binary without restarting
+// the agent.
+type PluginTool struct {
+	Name        string
+	Description string
+
+	// Cmd is the plugin binary and its arguments, e.g. []string{"./my-tool"}.
+	Cmd []string
+
+	// SchemaJSON is the tool's input JSON Schema. There's no Go struct to
+	// reflect it from, so it's supplied directly.
+	SchemaJSON string
+}
+
+// RegisterPlugin registers a PluginTool, the out-of-process counterpart
+// to Register.
+func (s *pollingAgent) RegisterPlugin(pt PluginTool) error {
+	if s.isPolling() {
+		return fmt.Errorf("tool must be registered before starting the service")
+	}
+
+	if _, exists := s.Tools[pt.Name]; exists {
+		return fmt.Errorf("tool with name '%s' already registered", pt.Name)
+	}
+
+	var schema interface{}
+	if err := json.Unmarshal([]byte(pt.SchemaJSON), &schema); err != nil {
+		return fmt.Errorf("failed to parse schema for plugin tool '%s': %v", pt.Name, err)
+	}
+
+	s.Tools[pt.Name] = Tool{
+		Name:        pt.Name,
+		Description: pt.Description,
+		schema:      schema,
+		plugin:      plugin.NewSupervisor(pt.Cmd, s.inferable.pluginMaxRestarts),
+	}
+	return nil
 }
 
 type pollingAgent struct {
-	Tools      map[string]Tool
-	inferable  *AgentRPC
-	ctx        context.Context
-	cancel     context.CancelFunc
-	retryAfter int
+	Tools       map[string]Tool
+	inferable   *AgentRPC
+	ctx         context.Context
+	cancel      context.CancelFunc
+	retryAfter  int
+	logger      Logger
+	middlewares []Middleware
+	pool        *workerPool
+	draining    atomic.Bool
+
+	inFlightMu sync.Mutex
+	inFlight   map[string]context.CancelFunc
 }
 
 type callMessage struct {
 	Id       string      `json:"id"`
 	Function string      `json:"function"`
 	Input    interface{} `json:"input"`
+
+	// Timeout, in milliseconds, is the server-provided deadline for this
+	// job. Zero means no deadline.
+	Timeout int64 `json:"timeout,omitempty"`
 }
 
 type callResultMeta struct {
@@ -61,12 +130,24 @@ func (s *pollingAgent) Register(fn Tool) error {
 		return fmt.Errorf("tool with name '%s' already registered", fn.Name)
 	}
 
-	// Validate that the function has exactly one argument and it's a struct
+	// Validate that the function has exactly one input-struct argument
+	// (plus, optionally, a leading context.Context and/or a trailing
+	// ResultStream) and that the input argument is a struct.
 	fnType := reflect.TypeOf(fn.Handler)
-	if fnType.NumIn() != 1 {
-		return fmt.Errorf("tool '%s' must have exactly one argument", fn.Name)
+	streamingType := reflect.TypeOf((*ResultStream)(nil)).Elem()
+
+	argIndex := 0
+	if fnType.NumIn() > 0 && fnType.In(0) == contextType {
+		argIndex = 1
+	}
+
+	switch {
+	case fnType.NumIn() == argIndex+1:
+	case fnType.NumIn() == argIndex+2 && fnType.In(argIndex+1) == streamingType:
+	default:
+		return fmt.Errorf("tool '%s' must have exactly one argument (after an optional leading context.Context), or two with the second a ResultStream", fn.Name)
 	}
-	arg1Type := fnType.In(0)
+	arg1Type := fnType.In(argIndex)
 
 	// Set the argument type to the referenced type
 	if arg1Type.Kind() == reflect.Ptr {
@@ -77,8 +158,11 @@ func (s *pollingAgent) Register(fn Tool) error {
 		return fmt.Errorf("tool '%s' first argument must be a struct or a pointer to a struct", fn.Name)
 	}
 
-	// Get the schema for the input struct
-	reflector := jsonschema.Reflector{DoNotReference: true, Anonymous: true, AllowAdditionalProperties: false}
+	// Get the schema for the input struct. DoNotReference is left at its
+	// zero value (false) so nested struct types are reflected as
+	// "$ref": "#/$defs/Foo" entries in schema.Definitions, which inlineDefs
+	// below resolves into a single self-contained schema.
+	reflector := jsonschema.Reflector{Anonymous: true, AllowAdditionalProperties: false}
 	schema := reflector.Reflect(reflect.New(arg1Type).Interface())
 
 	if schema == nil {
@@ -95,13 +179,8 @@ func (s *pollingAgent) Register(fn Tool) error {
 		defs = schema
 	}
 
-	defsString, err := json.Marshal(defs)
-	if err != nil {
-		return fmt.Errorf("failed to marshal schema for tool '%s': %v", fn.Name, err)
-	}
-
-	if strings.Contains(string(defsString), "\"$ref\":\"#/$defs") {
-		return fmt.Errorf("schema for tool '%s' contains a $ref to an external definition. this is currently not supported. see https://go.inferable.ai/go-schema-limitation for details", fn.Name)
+	if err := inlineDefs(defs, schema.Definitions); err != nil {
+		return fmt.Errorf("failed to inline schema for tool '%s': %v", fn.Name, err)
 	}
 
 	defs.AdditionalProperties = jsonschema.FalseSchema
@@ -113,6 +192,10 @@ func (s *pollingAgent) Register(fn Tool) error {
 
 // Start polling for jobs, registers the machine, and starts polling for messages
 func (s *pollingAgent) Listen() error {
+	if s.inferable.transport == TransportGRPC {
+		return fmt.Errorf("AgentRPCOptions.Transport is TransportGRPC; call ServeGRPC instead of Listen")
+	}
+
 	_, err := s.inferable.registerMachine(s)
 	if err != nil {
 		return fmt.Errorf("failed to register machine: %v", err)
@@ -120,6 +203,8 @@ func (s *pollingAgent) Listen() error {
 
 	s.ctx, s.cancel = context.WithCancel(context.Background())
 	s.retryAfter = DefaultRetryAfter
+	s.pool = newWorkerPool(s.inferable.maxConcurrency, s.Tools)
+	s.inFlight = make(map[string]context.CancelFunc)
 
 	go func() {
 		failureCount := 0
@@ -136,29 +221,109 @@ func (s *pollingAgent) Listen() error {
 					failureCount++
 
 					if failureCount > MaxConsecutivePollFailures {
-						log.Printf("Too many consecutive poll failures, exiting service")
+						s.logger.Error("too many consecutive poll failures, exiting service", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID, "attempt", failureCount)
 						s.Unlisten()
 					}
 
-					log.Printf("Failed to poll: %v", err)
+					s.logger.Warn("failed to poll", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID, "attempt", failureCount, "error", err)
 				}
 			}
 		}
 	}()
 
-	log.Printf("started and polling for messages")
+	s.logger.Info("started and polling for messages", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID)
 	return nil
 }
 
-// Stop stops the service and cancels the polling
+// Unlisten stops the service and cancels the polling. If AgentRPCOptions.DrainTimeout
+// is set, it first drains (see Drain) for up to that long before cancelling the
+// context.Context passed into handlers; otherwise it cancels immediately.
 func (s *pollingAgent) Unlisten() {
-	if s.cancel != nil {
-		s.cancel()
-		log.Printf("stopped polling for messages")
+	if s.cancel == nil {
+		return
+	}
+
+	if s.pool != nil && s.inferable.drainTimeout > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), s.inferable.drainTimeout)
+		defer cancel()
+		if err := s.Drain(ctx); err != nil {
+			s.logger.Warn("drain timed out with jobs still in flight", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID, "error", err)
+		}
+	}
+
+	s.cancel()
+	s.logger.Info("stopped polling for messages", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID)
+}
+
+// Drain stops the poll loop from fetching new jobs and waits for every
+// in-flight handleMessage call to finish persisting its result. If ctx
+// expires first, Drain cancels every job still outstanding and reports
+// its result as a "rejection" explaining the machine is shutting down, so
+// the control plane can reschedule it immediately rather than waiting for
+// the poll lease to lapse, then returns ctx.Err(). Only jobs whose
+// handleMessage has started are cancelled and rejected this way; jobs still
+// waiting in the worker pool for a free slot are not, and run once a slot
+// frees up.
+func (s *pollingAgent) Drain(ctx context.Context) error {
+	s.draining.Store(true)
+	defer s.draining.Store(false)
+
+	if s.pool == nil {
+		return nil
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		s.pool.wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		s.rejectInFlight("machine is shutting down")
+		return ctx.Err()
+	}
+}
+
+// rejectInFlight cancels and persists a "rejection" result for every job
+// still tracked in s.inFlight, used when Drain's deadline expires with
+// handler goroutines still running.
+func (s *pollingAgent) rejectInFlight(reason string) {
+	s.inFlightMu.Lock()
+	jobIDs := make([]string, 0, len(s.inFlight))
+	for jobID, cancel := range s.inFlight {
+		jobIDs = append(jobIDs, jobID)
+		cancel()
+	}
+	s.inFlightMu.Unlock()
+
+	for _, jobID := range jobIDs {
+		if err := s.persistJobResult(jobID, callResult{Result: reason, ResultType: "rejection"}); err != nil {
+			s.logger.Warn("failed to persist rejection for in-flight job", "job_id", jobID, "cluster_id", s.inferable.clusterID, "error", err)
+		}
 	}
 }
 
 func (s *pollingAgent) poll() error {
+	if s.draining.Load() {
+		s.logger.Debug("draining, skipping poll", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID)
+		return nil
+	}
+
+	// The worker pool is saturated: don't fetch more jobs than we could
+	// run right now, so the control plane can hand them to another
+	// machine instead of having them sit acknowledged-but-unstarted here.
+	batchSize := s.pool.available()
+	if batchSize <= 0 {
+		s.logger.Debug("worker pool saturated, skipping poll", "cluster_id", s.inferable.clusterID, "machine_id", s.inferable.machineID)
+		return nil
+	}
+	if batchSize > 10 {
+		batchSize = 10
+	}
+
 	headers := map[string]string{
 		"Authorization":          "Bearer " + s.inferable.apiSecret,
 		"X-Machine-ID":           s.inferable.machineID,
@@ -176,7 +341,7 @@ func (s *pollingAgent) poll() error {
 	}
 
 	options := client.FetchDataOptions{
-		Path:    fmt.Sprintf("/clusters/%s/jobs?acknowledge=true&tools=%s&status=pending&limit=10&waitTime=20", s.inferable.clusterID, toolList),
+		Path:    fmt.Sprintf("/clusters/%s/jobs?acknowledge=true&tools=%s&status=pending&limit=%d&waitTime=20", s.inferable.clusterID, toolList, batchSize),
 		Method:  "GET",
 		Headers: headers,
 	}
@@ -195,6 +360,7 @@ func (s *pollingAgent) poll() error {
 		for _, v := range retryAfter {
 			if i, err := strconv.Atoi(v); err == nil {
 				s.retryAfter = i
+				s.logger.Debug("server requested retry-after", "cluster_id", s.inferable.clusterID, "retry_after_seconds", i, "http_status", status)
 			}
 		}
 	}
@@ -206,16 +372,32 @@ func (s *pollingAgent) poll() error {
 		return fmt.Errorf("failed to parse poll response: %v", err)
 	}
 
-	errors := []string{}
+	// Dispatch each job to the worker pool instead of handling it inline,
+	// so a slow handler no longer blocks acknowledgement of the rest of
+	// the batch. Per-job errors are logged rather than aggregated, since
+	// they now complete asynchronously with respect to poll() returning.
+	//
+	// draining is re-checked here, not just at the top of poll(), because
+	// the long-poll HTTP call above can take up to 20s: Drain may start
+	// (and its WaitGroup.Wait() may already be running) after this poll()
+	// call passed its initial check but before it got here. Submitting
+	// anyway would both race pool.wg's Add against Drain's already-running
+	// Wait and hand a job to a goroutine whose context Unlisten is about
+	// to cancel mid-flight, exactly what Drain exists to prevent.
 	for _, msg := range parsed {
-		err := s.handleMessage(msg)
-		if err != nil {
-			errors = append(errors, err.Error())
+		msg := msg
+		if s.draining.Load() {
+			s.logger.Debug("draining, rejecting fetched job instead of dispatching", "job_id", msg.Id, "tool", msg.Function, "cluster_id", s.inferable.clusterID)
+			if err := s.persistJobResult(msg.Id, callResult{Result: "machine is shutting down", ResultType: "rejection"}); err != nil {
+				s.logger.Warn("failed to persist rejection for undispatched job", "job_id", msg.Id, "cluster_id", s.inferable.clusterID, "error", err)
+			}
+			continue
 		}
-	}
-
-	if len(errors) > 0 {
-		return fmt.Errorf("failed to handle messages: %v", errors)
+		s.pool.submit(msg.Function, func() {
+			if err := s.handleMessage(msg); err != nil {
+				s.logger.Error("failed to handle message", "job_id", msg.Id, "tool", msg.Function, "cluster_id", s.inferable.clusterID, "error", err)
+			}
+		})
 	}
 
 	return nil
@@ -223,51 +405,172 @@ func (s *pollingAgent) poll() error {
 
 func (s *pollingAgent) handleMessage(msg callMessage) error {
 	// Find the target function
-	fn, ok := s.Tools[msg.Function]
-	if !ok {
-		log.Printf("Received call for unknown function: %s", msg.Function)
+	inputJson, err := json.Marshal(msg.Input)
+	if err != nil {
+		if err := s.persistJobResult(msg.Id, callResult{Result: err.Error(), ResultType: "rejection"}); err != nil {
+			return fmt.Errorf("failed to persist job result: %v", err)
+		}
 		return nil
 	}
 
-	// Create a new instance of the function's input type
-	fnType := reflect.TypeOf(fn.Handler)
-	argType := fnType.In(0)
-	argPtr := reflect.New(argType)
+	call := &Call{
+		JobID:     msg.Id,
+		Tool:      msg.Function,
+		ClusterID: s.inferable.clusterID,
+		MachineID: s.inferable.machineID,
+		Input:     inputJson,
+	}
 
-	inputJson, err := json.Marshal(msg.Input)
+	ctx, finish := s.beginJob(msg.Id, time.Duration(msg.Timeout)*time.Millisecond)
+	outcome, err := s.handlerChain()(ctx, call)
+	timedOut := finish()
 
-	if err != nil {
-		result := callResult{
-			Result:     err.Error(),
-			ResultType: "rejection",
+	if timedOut {
+		if err := s.persistJobResult(msg.Id, callResult{Result: "job exceeded its deadline", ResultType: "cancelled"}); err != nil {
+			return fmt.Errorf("failed to persist job result: %v", err)
 		}
+		return nil
+	}
 
-		// Persist the job result
-		if err := s.persistJobResult(msg.Id, result); err != nil {
+	if err != nil {
+		if err := s.persistJobResult(msg.Id, callResult{Result: err.Error(), ResultType: "rejection"}); err != nil {
 			return fmt.Errorf("failed to persist job result: %v", err)
 		}
+		return nil
+	}
+
+	result := callResult{
+		Result:     json.RawMessage(outcome.Result),
+		ResultType: outcome.ResultType,
+	}
+
+	// Persist the job result
+	if err := s.persistJobResult(msg.Id, result); err != nil {
+		return fmt.Errorf("failed to persist job result: %v", err)
+	}
+
+	return nil
+}
+
+// handlerChain wraps the reflect-based tool dispatch (baseHandler) with
+// every registered middleware, in registration order. It is built fresh
+// per call from s.middlewares, which AgentRPC.Use writes without any
+// synchronization, so Use must still be called before Listen/ServeGRPC.
+func (s *pollingAgent) handlerChain() Handler {
+	return chain(s.baseHandler, s.middlewares)
+}
+
+// baseHandler is the innermost Handler: it looks up the tool, invokes it
+// via reflection, and marshals the result back to JSON. It carries the
+// same "received call for unknown function" / duration logging the HTTP
+// transport always had, so wrapping it in middlewares doesn't change
+// default observability.
+func (s *pollingAgent) baseHandler(ctx context.Context, call *Call) (*CallOutcome, error) {
+	fn, ok := s.Tools[call.Tool]
+	if !ok {
+		s.logger.Warn("received call for unknown function", "job_id", call.JobID, "tool", call.Tool, "cluster_id", call.ClusterID)
+		out, _ := json.Marshal(fmt.Sprintf("unknown function: %s", call.Tool))
+		return &CallOutcome{Result: out, ResultType: "rejection"}, nil
 	}
 
-	err = json.Unmarshal(inputJson, argPtr.Interface())
+	resultValue, resultType, durationMs, err := s.invokeTool(ctx, call.JobID, fn, call.Input)
 	if err != nil {
-		result := callResult{
-			Result:     err.Error(),
-			ResultType: "rejection",
-		}
+		return nil, err
+	}
 
-		// Persist the job result
-		if err := s.persistJobResult(msg.Id, result); err != nil {
-			return fmt.Errorf("failed to persist job result: %v", err)
+	resultJSON, err := json.Marshal(resultValue)
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("handled job", "job_id", call.JobID, "tool", call.Tool, "cluster_id", call.ClusterID, "result_type", resultType, "duration_ms", durationMs)
+
+	return &CallOutcome{Result: resultJSON, ResultType: resultType}, nil
+}
+
+// invokeTool unmarshals inputJson into fn's input type and calls its
+// Handler via reflection. It is the single dispatch path shared by the
+// HTTP long-poll transport (handleMessage) and any other transport (e.g.
+// the gRPC transport) registered against the same Tools map, so schema
+// generation and call semantics never drift between them.
+//
+// If fn is a PluginTool (fn.plugin is set), invokeTool skips reflection
+// entirely and calls the plugin process instead, reporting a crash or
+// restart-budget exhaustion as a "rejection" rather than an error that
+// would otherwise be indistinguishable from an in-process panic.
+//
+// If fn.Handler takes a ResultStream as the argument after its input
+// struct, invokeTool wires up a streaming writer for the call and, if
+// every chunk made it to
+// the control plane over the stream, returns a nil resultValue: the
+// terminal persistJobResult call the caller makes regardless is the
+// stream's final frame.
+//
+// If fn.Handler takes a context.Context as its first argument, the ctx
+// passed in here (a per-job context from beginJob, cancelled on deadline
+// or AgentRPC.Cancel) is forwarded to it in place of the input struct,
+// which shifts to the next argument.
+func (s *pollingAgent) invokeTool(ctx context.Context, jobID string, fn Tool, inputJson []byte) (resultValue interface{}, resultType string, durationMs int64, err error) {
+	if fn.plugin != nil {
+		start := time.Now()
+		output, callErr := fn.plugin.Call(ctx, jobID, inputJson)
+		durationMs = time.Since(start).Milliseconds()
+		if callErr != nil {
+			return callErr.Error(), "rejection", durationMs, nil
 		}
+		return json.RawMessage(output), "resolution", durationMs, nil
+	}
+
+	fnType := reflect.TypeOf(fn.Handler)
+
+	argIndex := 0
+	var args []reflect.Value
+	if fnType.In(0) == contextType {
+		args = append(args, reflect.ValueOf(ctx))
+		argIndex = 1
+	}
+
+	argType := fnType.In(argIndex)
+	argPtr := reflect.New(argType)
+
+	if err = json.Unmarshal(inputJson, argPtr.Interface()); err != nil {
+		return nil, "", 0, err
+	}
+
+	args = append(args, argPtr.Elem())
+
+	streamingType := reflect.TypeOf((*ResultStream)(nil)).Elem()
+	streaming := fnType.NumIn() == argIndex+2 && fnType.In(argIndex+1) == streamingType
+	var stream *resultStreamWriter
+	if streaming {
+		stream = newResultStreamWriter(s, jobID)
+		args = append(args, reflect.ValueOf(stream))
 	}
 
 	start := time.Now()
-	// Call the function with the unmarshaled argument
 	fnValue := reflect.ValueOf(fn.Handler)
-	returnValues := fnValue.Call([]reflect.Value{argPtr.Elem()})
+	returnValues := fnValue.Call(args)
+
+	resultType = "resolution"
+
+	if streaming {
+		if errVal, ok := returnValues[0].Interface().(error); ok && errVal != nil {
+			resultType = "rejection"
+			resultValue = errVal.Error()
+		}
+
+		if fallback := stream.Close(); fallback != nil && resultType != "rejection" {
+			// Streaming degraded (the control plane doesn't support the
+			// stream endpoint): fall back to returning everything the
+			// handler wrote as a single result, same as a non-streaming
+			// handler would have.
+			resultValue = fallback
+		}
+
+		return resultValue, resultType, time.Since(start).Milliseconds(), nil
+	}
 
-	resultType := "resolution"
-	resultValue := returnValues[0].Interface()
+	resultValue = returnValues[0].Interface()
 
 	for _, v := range returnValues {
 		// Check if ANY of the return values is an error
@@ -294,20 +597,42 @@ func (s *pollingAgent) handleMessage(msg callMessage) error {
 		}
 	}
 
-	result := callResult{
-		Result:     resultValue,
-		ResultType: resultType,
-		Meta: callResultMeta{
-			FunctionExecutionTime: int64(time.Since(start).Milliseconds()),
-		},
+	return resultValue, resultType, time.Since(start).Milliseconds(), nil
+}
+
+// toolNames returns the names of every registered tool. grpcDispatcherAdapter
+// uses it to satisfy grpc.Dispatcher.Tools, so the gRPC transport sees the
+// same tool set as the HTTP long-poll transport.
+func (s *pollingAgent) toolNames() []string {
+	names := make([]string, 0, len(s.Tools))
+	for name := range s.Tools {
+		names = append(names, name)
 	}
+	return names
+}
 
-	// Persist the job result
-	if err := s.persistJobResult(msg.Id, result); err != nil {
-		return fmt.Errorf("failed to persist job result: %v", err)
+// dispatchJSON runs tool "function" against a JSON-encoded input, through
+// the same middleware chain as the HTTP transport, and returns a
+// JSON-encoded result alongside its resultType. Transports that speak
+// bytes rather than Go values (like grpc.Dispatcher) call this instead of
+// handleMessage.
+func (s *pollingAgent) dispatchJSON(ctx context.Context, jobID, function string, input []byte) ([]byte, string, int64) {
+	start := time.Now()
+	outcome, err := s.handlerChain()(ctx, &Call{
+		JobID:     jobID,
+		Tool:      function,
+		ClusterID: s.inferable.clusterID,
+		MachineID: s.inferable.machineID,
+		Input:     input,
+	})
+	durationMs := time.Since(start).Milliseconds()
+
+	if err != nil {
+		out, _ := json.Marshal(err.Error())
+		return out, "rejection", durationMs
 	}
 
-	return nil
+	return outcome.Result, outcome.ResultType, durationMs
 }
 
 func (s *pollingAgent) persistJobResult(jobID string, result callResult) error {
@@ -0,0 +1,101 @@
+package agentrpc
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// inlineDefs walks a jsonschema.Reflector's output and recursively inlines
+// every "$ref": "#/$defs/..." into the schema that references it, so each
+// tool ends up with a single self-contained schema instead of one that
+// depends on sibling definitions the control plane has no way to resolve.
+//
+// defs is the root schema's Definitions map (its $defs). visited guards
+// against mutually referential structs: it tracks the definitions
+// currently being inlined along the current path, and returns an error
+// naming the offending type rather than recursing forever.
+func inlineDefs(schema *jsonschema.Schema, defs jsonschema.Definitions) error {
+	visited := map[string]bool{}
+	// The root is usually itself one of defs (Register passes
+	// schema.Definitions[name]); mark it so a $ref back to it is a cycle.
+	for name, def := range defs {
+		if def == schema {
+			visited[name] = true
+		}
+	}
+	return inlineDefsVisited(schema, defs, visited)
+}
+
+func inlineDefsVisited(schema *jsonschema.Schema, defs jsonschema.Definitions, visited map[string]bool) error {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		name := refDefName(schema.Ref)
+		if visited[name] {
+			return fmt.Errorf("cyclic schema reference detected for type %q; inlining $defs does not support mutually recursive structs", name)
+		}
+
+		def, ok := defs[name]
+		if !ok {
+			return fmt.Errorf("schema references unknown definition %q", name)
+		}
+
+		visited[name] = true
+		defer delete(visited, name)
+
+		// Substitute the referenced definition in place, then keep
+		// walking the substituted schema so nested $refs are inlined too.
+		ref := schema.Ref
+		*schema = *def
+		_ = ref
+	}
+
+	if schema.Type == "object" {
+		schema.AdditionalProperties = jsonschema.FalseSchema
+	}
+
+	if schema.Properties != nil {
+		for pair := schema.Properties.Oldest(); pair != nil; pair = pair.Next() {
+			if err := inlineDefsVisited(pair.Value, defs, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	if schema.Items != nil {
+		if err := inlineDefsVisited(schema.Items, defs, visited); err != nil {
+			return err
+		}
+	}
+
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties != jsonschema.FalseSchema && schema.AdditionalProperties != jsonschema.TrueSchema {
+		if err := inlineDefsVisited(schema.AdditionalProperties, defs, visited); err != nil {
+			return err
+		}
+	}
+
+	for _, sub := range []*jsonschema.Schema{schema.Not} {
+		if err := inlineDefsVisited(sub, defs, visited); err != nil {
+			return err
+		}
+	}
+	for _, list := range [][]*jsonschema.Schema{schema.AllOf, schema.AnyOf, schema.OneOf} {
+		for _, sub := range list {
+			if err := inlineDefsVisited(sub, defs, visited); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// refDefName extracts "Foo" out of a "#/$defs/Foo" JSON pointer.
+func refDefName(ref string) string {
+	const prefix = "#/$defs/"
+	return strings.TrimPrefix(ref, prefix)
+}
@@ -0,0 +1,84 @@
+package agentrpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/agentrpc/agentrpc/sdk-go/internal/util"
+)
+
+func TestRegisterFuncWithNestedStruct(t *testing.T) {
+	_, _, _, apiEndpoint := util.GetTestVars()
+
+	i, _ := New(AgentRPCOptions{
+		APIEndpoint: apiEndpoint,
+		APISecret:   "sk_secret_123",
+	})
+
+	type Address struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+	}
+
+	type Contact struct {
+		Email   string  `json:"email"`
+		Address Address `json:"address"`
+	}
+
+	type TestInput struct {
+		Name    string  `json:"name"`
+		Contact Contact `json:"contact"`
+	}
+
+	testFunc := func(input TestInput) string { return input.Name }
+	err := i.Register(Tool{
+		Handler:     testFunc,
+		Name:        "NestedFunc",
+		Description: "Function with a deeply nested input struct",
+	})
+	require.NoError(t, err)
+
+	schemaJSON, err := json.Marshal(i.tools.Tools["NestedFunc"].schema)
+	require.NoError(t, err)
+
+	// The emitted schema must be self-contained: no dangling $defs
+	// references, and additionalProperties: false preserved on every
+	// inlined object.
+	assert.NotContains(t, string(schemaJSON), "$ref")
+	assert.Contains(t, string(schemaJSON), "\"city\"")
+	assert.Contains(t, string(schemaJSON), "\"additionalProperties\":false")
+}
+
+func TestRegisterFuncWithMutuallyRecursiveStruct(t *testing.T) {
+	_, _, _, apiEndpoint := util.GetTestVars()
+
+	i, _ := New(AgentRPCOptions{
+		APIEndpoint: apiEndpoint,
+		APISecret:   "sk_secret_123",
+	})
+
+	testFunc := func(input A) int { return input.Value }
+	err := i.Register(Tool{
+		Handler:     testFunc,
+		Name:        "CyclicFunc",
+		Description: "Function with a mutually referential input struct",
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cyclic schema reference")
+}
+
+// A and B are mutually referential, declared at package scope because Go
+// doesn't allow mutually recursive local type declarations.
+type A struct {
+	Value int `json:"value"`
+	Next  *B  `json:"next,omitempty"`
+}
+
+type B struct {
+	Value int `json:"value"`
+	Next  *A  `json:"next,omitempty"`
+}
@@ -0,0 +1,181 @@
+package agentrpc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/agentrpc/agentrpc/sdk-go/internal/client"
+)
+
+const (
+	// DefaultStreamFlushInterval is how often buffered chunks are flushed
+	// to the control plane when a handler isn't producing enough of them
+	// to fill DefaultStreamMaxBufferSize on its own.
+	DefaultStreamFlushInterval = 250 * time.Millisecond
+	// DefaultStreamMaxBufferSize is how many chunks accumulate before a
+	// flush is triggered early, regardless of the flush interval.
+	DefaultStreamMaxBufferSize = 50
+)
+
+// ResultStream lets a Tool.Handler emit partial results as it produces
+// them, instead of returning a single value once it's done. Handlers opt
+// in by taking a ResultStream as the argument after their input struct:
+//
+//	func(input TestInput, stream agentrpc.ResultStream) error
+type ResultStream interface {
+	// Write enqueues chunk to be flushed to the control plane as an
+	// NDJSON frame. Flushing happens on a background interval, except
+	// that the Write which fills the buffer flushes it itself and so
+	// blocks on that network call.
+	Write(chunk interface{}) error
+}
+
+// resultStreamWriter is the concrete ResultStream handed to streaming
+// handlers. It batches writes and POSTs them to
+// /jobs/{id}/result/stream as NDJSON. If the control plane responds 405
+// (the endpoint isn't available), it degrades to buffering every chunk in
+// memory so invokeTool can fall back to the existing single-shot
+// persistJobResult path once the handler returns.
+type resultStreamWriter struct {
+	agent *pollingAgent
+	jobID string
+
+	flushInterval time.Duration
+	maxBufferSize int
+
+	mu       sync.Mutex
+	buf      []interface{}
+	degraded bool
+	fallback []interface{}
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+func newResultStreamWriter(agent *pollingAgent, jobID string) *resultStreamWriter {
+	w := &resultStreamWriter{
+		agent:         agent,
+		jobID:         jobID,
+		flushInterval: DefaultStreamFlushInterval,
+		maxBufferSize: DefaultStreamMaxBufferSize,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+
+	go w.flushLoop()
+
+	return w
+}
+
+func (w *resultStreamWriter) flushLoop() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.stop:
+			w.flush()
+			return
+		}
+	}
+}
+
+// Write implements ResultStream.
+func (w *resultStreamWriter) Write(chunk interface{}) error {
+	w.mu.Lock()
+	if w.degraded {
+		w.fallback = append(w.fallback, chunk)
+		full := len(w.fallback)
+		w.mu.Unlock()
+		_ = full
+		return nil
+	}
+	w.buf = append(w.buf, chunk)
+	shouldFlush := len(w.buf) >= w.maxBufferSize
+	w.mu.Unlock()
+
+	if shouldFlush {
+		w.flush()
+	}
+	return nil
+}
+
+// flush POSTs every buffered chunk as newline-delimited JSON. On a 405, or
+// a 404 reported without an error, it degrades: future chunks (and
+// whatever was in the buffer) accumulate in
+// w.fallback for Close to hand back to invokeTool instead. On any other
+// failure (a transient 5xx, timeout, or network error), pending is
+// requeued onto w.buf so the next flush retries it instead of the chunks
+// being silently lost.
+func (w *resultStreamWriter) flush() {
+	w.mu.Lock()
+	if w.degraded || len(w.buf) == 0 {
+		w.mu.Unlock()
+		return
+	}
+	pending := w.buf
+	w.buf = nil
+	w.mu.Unlock()
+
+	var body bytes.Buffer
+	for _, chunk := range pending {
+		line, err := json.Marshal(chunk)
+		if err != nil {
+			w.agent.logger.Warn("failed to marshal stream chunk", "job_id", w.jobID, "error", err)
+			continue
+		}
+		body.Write(line)
+		body.WriteByte('\n')
+	}
+
+	options := client.FetchDataOptions{
+		Path:   fmt.Sprintf("/clusters/%s/jobs/%s/result/stream", w.agent.inferable.clusterID, w.jobID),
+		Method: "POST",
+		Headers: map[string]string{
+			"Authorization":          "Bearer " + w.agent.inferable.apiSecret,
+			"X-Machine-ID":           w.agent.inferable.machineID,
+			"X-Machine-SDK-Version":  Version,
+			"X-Machine-SDK-Language": "go",
+			"Content-Type":           "application/x-ndjson",
+		},
+		Body: body.String(),
+	}
+
+	_, _, err, status := w.agent.inferable.fetchData(options)
+	if status == 405 || (err == nil && status == 404) {
+		w.agent.logger.Debug("streaming result endpoint unavailable, degrading to single-shot result", "job_id", w.jobID, "http_status", status)
+		w.mu.Lock()
+		w.degraded = true
+		w.fallback = append(w.fallback, pending...)
+		w.mu.Unlock()
+		return
+	}
+	if err != nil {
+		w.agent.logger.Warn("failed to flush stream chunk, will retry on next flush", "job_id", w.jobID, "error", err)
+		w.mu.Lock()
+		w.buf = append(pending, w.buf...)
+		w.mu.Unlock()
+	}
+}
+
+// Close stops the flush loop and returns the buffered fallback result if
+// the writer degraded to single-shot mode or its final flush still
+// couldn't get some chunks out, or nil if every chunk made it out over
+// the stream.
+func (w *resultStreamWriter) Close() []interface{} {
+	close(w.stop)
+	<-w.done
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.degraded && len(w.buf) == 0 {
+		return nil
+	}
+	return append(w.fallback, w.buf...)
+}
@@ -0,0 +1,24 @@
+package agentrpcpb
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// jsonCodec implements encoding.Codec using JSON instead of the protobuf
+// wire format: JobDispatch/JobResult are plain Go structs, not
+// protoc-generated proto.Message implementations, since this repo has no
+// protoc toolchain to generate real .pb.go bindings from agentrpc.proto.
+// Registering it under the name "proto" makes grpc-go use it by default
+// for any client or server built against this package, with no other
+// configuration required on either side.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "proto" }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -0,0 +1,20 @@
+// Package agentrpcpb contains the Go bindings for agentrpc.proto: the
+// message types and the AgentRPCTransport client/server pair. See this
+// transport package's doc.go for why these are hand-written instead of
+// protoc output.
+package agentrpcpb
+
+// JobDispatch mirrors the polling HTTP transport's callMessage.
+type JobDispatch struct {
+	Id       string `json:"id"`
+	Function string `json:"function"`
+	Input    []byte `json:"input"`
+}
+
+// JobResult mirrors callResult.
+type JobResult struct {
+	JobId                   string `json:"job_id"`
+	Result                  []byte `json:"result"`
+	ResultType              string `json:"result_type"`
+	FunctionExecutionTimeMs int64  `json:"function_execution_time_ms"`
+}
@@ -0,0 +1,118 @@
+package agentrpcpb
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// AgentRPCTransportClient is the client API for the AgentRPCTransport
+// service.
+type AgentRPCTransportClient interface {
+	Dispatch(ctx context.Context, opts ...grpc.CallOption) (AgentRPCTransport_DispatchClient, error)
+}
+
+type agentRPCTransportClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewAgentRPCTransportClient returns an AgentRPCTransportClient using cc.
+func NewAgentRPCTransportClient(cc grpc.ClientConnInterface) AgentRPCTransportClient {
+	return &agentRPCTransportClient{cc}
+}
+
+func (c *agentRPCTransportClient) Dispatch(ctx context.Context, opts ...grpc.CallOption) (AgentRPCTransport_DispatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &agentRPCTransportServiceDesc.Streams[0], "/agentrpc.transport.v1.AgentRPCTransport/Dispatch", opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &agentRPCTransportDispatchClient{stream}, nil
+}
+
+// AgentRPCTransport_DispatchClient is the caller's side of the Dispatch
+// stream: it sends jobs and receives their results, in either order.
+type AgentRPCTransport_DispatchClient interface {
+	Send(*JobDispatch) error
+	Recv() (*JobResult, error)
+	grpc.ClientStream
+}
+
+type agentRPCTransportDispatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *agentRPCTransportDispatchClient) Send(m *JobDispatch) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *agentRPCTransportDispatchClient) Recv() (*JobResult, error) {
+	m := new(JobResult)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AgentRPCTransportServer is the server API for the AgentRPCTransport
+// service.
+type AgentRPCTransportServer interface {
+	Dispatch(AgentRPCTransport_DispatchServer) error
+}
+
+// UnimplementedAgentRPCTransportServer can be embedded for forward
+// compatibility.
+type UnimplementedAgentRPCTransportServer struct{}
+
+func (UnimplementedAgentRPCTransportServer) Dispatch(AgentRPCTransport_DispatchServer) error {
+	return status.Error(codes.Unimplemented, "method Dispatch not implemented")
+}
+
+// RegisterAgentRPCTransportServer registers srv on s.
+func RegisterAgentRPCTransportServer(s grpc.ServiceRegistrar, srv AgentRPCTransportServer) {
+	s.RegisterService(&agentRPCTransportServiceDesc, srv)
+}
+
+func _AgentRPCTransport_Dispatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AgentRPCTransportServer).Dispatch(&agentRPCTransportDispatchServer{stream})
+}
+
+// AgentRPCTransport_DispatchServer is the implementation's side of the
+// Dispatch stream: it receives jobs and sends back their results.
+type AgentRPCTransport_DispatchServer interface {
+	Send(*JobResult) error
+	Recv() (*JobDispatch, error)
+	grpc.ServerStream
+}
+
+type agentRPCTransportDispatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *agentRPCTransportDispatchServer) Send(m *JobResult) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *agentRPCTransportDispatchServer) Recv() (*JobDispatch, error) {
+	m := new(JobDispatch)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var agentRPCTransportServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agentrpc.transport.v1.AgentRPCTransport",
+	HandlerType: (*AgentRPCTransportServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Dispatch",
+			Handler:       _AgentRPCTransport_Dispatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "sdk-go/transport/grpc/agentrpc.proto",
+}
@@ -0,0 +1,15 @@
+// Package grpc exposes a pollingAgent's registered Tools over gRPC instead
+// of the HTTP long-poll transport, for embedding agentrpc next to existing
+// gRPC services.
+//
+// agentrpc.proto is the source of truth for the wire types, but the
+// agentrpcpb package committed alongside it is hand-written rather than
+// protoc-generated: this repo has no protoc/protoc-gen-go-grpc in its
+// build pipeline. agentrpcpb/codec.go registers a JSON codec under grpc's
+// "proto" name so the client/server pair works without the protobuf
+// runtime. Once protoc is available, regenerate properly with:
+//
+//	protoc --go_out=. --go-grpc_out=. sdk-go/transport/grpc/agentrpc.proto
+//
+// and delete agentrpcpb/codec.go.
+package grpc
@@ -0,0 +1,111 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"sync/atomic"
+
+	"google.golang.org/grpc"
+
+	agentrpcpb "github.com/agentrpc/agentrpc/sdk-go/transport/grpc/agentrpcpb"
+)
+
+// Dispatcher is implemented by the polling agent. It decouples this
+// transport from the agentrpc package's reflect-based caller so both the
+// HTTP long-poll path and this gRPC path share the same schema generation
+// and dispatch code instead of each reimplementing it.
+type Dispatcher interface {
+	// Dispatch runs the named tool against a JSON-encoded input and returns
+	// a JSON-encoded result, a resultType ("resolution"/"rejection"/
+	// "interrupt"), and the handler's execution time in milliseconds.
+	Dispatch(ctx context.Context, jobID, function string, input []byte) (result []byte, resultType string, execMs int64)
+
+	// Tools returns the names of every registered tool, for the in-flight
+	// gauge's keyspace.
+	Tools() []string
+}
+
+// Server adapts a Dispatcher to the AgentRPCTransport gRPC service.
+type Server struct {
+	agentrpcpb.UnimplementedAgentRPCTransportServer
+
+	dispatcher Dispatcher
+
+	// inFlight tracks per-tool concurrency, incremented and decremented
+	// around each job run by Dispatch.
+	inFlight map[string]*atomic.Int64
+}
+
+// NewServer returns a Server dispatching to d.
+func NewServer(d Dispatcher) *Server {
+	inFlight := make(map[string]*atomic.Int64)
+	for _, name := range d.Tools() {
+		inFlight[name] = &atomic.Int64{}
+	}
+	return &Server{dispatcher: d, inFlight: inFlight}
+}
+
+// Register mounts the AgentRPCTransport service on grpcServer.
+func (s *Server) Register(grpcServer *grpc.Server) {
+	agentrpcpb.RegisterAgentRPCTransportServer(grpcServer, s)
+}
+
+// Dispatch runs for the lifetime of the caller's stream: every JobDispatch
+// received is run against s.dispatcher and its JobResult sent back, in the
+// order the jobs were received. It returns when the caller closes the
+// stream (io.EOF) or the stream errors.
+func (s *Server) Dispatch(stream agentrpcpb.AgentRPCTransport_DispatchServer) error {
+	for {
+		job, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		result := s.runJob(stream.Context(), job)
+		if err := stream.Send(result); err != nil {
+			return err
+		}
+	}
+}
+
+// runJob executes job against s.dispatcher, tracking the per-tool in-flight
+// gauge and converting a panic into a "rejection" result instead of taking
+// down the stream.
+func (s *Server) runJob(ctx context.Context, job *agentrpcpb.JobDispatch) (result *agentrpcpb.JobResult) {
+	if counter, ok := s.inFlight[job.Function]; ok {
+		counter.Add(1)
+		defer counter.Add(-1)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result = &agentrpcpb.JobResult{
+				JobId:      job.Id,
+				Result:     []byte(fmt.Sprintf("panic in %s: %v\n%s", job.Function, r, debug.Stack())),
+				ResultType: "rejection",
+			}
+		}
+	}()
+
+	data, resultType, execMs := s.dispatcher.Dispatch(ctx, job.Id, job.Function, job.Input)
+	return &agentrpcpb.JobResult{
+		JobId:                   job.Id,
+		Result:                  data,
+		ResultType:              resultType,
+		FunctionExecutionTimeMs: execMs,
+	}
+}
+
+// InFlight returns the current in-flight count for tool, for callers that
+// want to expose it as a Prometheus/OTel gauge.
+func (s *Server) InFlight(tool string) int64 {
+	if counter, ok := s.inFlight[tool]; ok {
+		return counter.Load()
+	}
+	return 0
+}
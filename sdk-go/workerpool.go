@@ -0,0 +1,71 @@
+package agentrpc
+
+import "sync"
+
+// DefaultMaxConcurrency is the worker pool size used when
+// AgentRPCOptions.MaxConcurrency is left at its zero value.
+const DefaultMaxConcurrency = 10
+
+// workerPool bounds how many jobs a pollingAgent executes at once, so a
+// slow handler can no longer block acknowledgement of the rest of a
+// batch. It also tracks in-flight work with a sync.WaitGroup so Unlisten
+// can drain outstanding jobs before tearing down the poll context.
+type workerPool struct {
+	global  chan struct{}
+	perTool map[string]chan struct{}
+	wg      sync.WaitGroup
+}
+
+func newWorkerPool(maxConcurrency int, tools map[string]Tool) *workerPool {
+	if maxConcurrency <= 0 {
+		maxConcurrency = DefaultMaxConcurrency
+	}
+
+	perTool := make(map[string]chan struct{})
+	for name, tool := range tools {
+		if tool.MaxConcurrency > 0 {
+			perTool[name] = make(chan struct{}, tool.MaxConcurrency)
+		}
+	}
+
+	return &workerPool{
+		global:  make(chan struct{}, maxConcurrency),
+		perTool: perTool,
+	}
+}
+
+// available reports how many additional jobs the pool could accept right
+// now, used by the poll loop to size its next batch request and preserve
+// back-pressure instead of over-fetching work it can't run yet.
+func (p *workerPool) available() int {
+	return cap(p.global) - len(p.global)
+}
+
+// submit spawns a goroutine that acquires both the global and (if set)
+// per-tool concurrency slots before running fn, tracking it in the pool's
+// WaitGroup. submit itself never blocks: the slot acquisition happens
+// inside the spawned goroutine, not the caller, so one saturated tool's
+// jobs queue up behind its own semaphore instead of stalling the caller
+// from submitting the rest of the batch.
+func (p *workerPool) submit(tool string, fn func()) {
+	toolSlot := p.perTool[tool]
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		p.global <- struct{}{}
+		defer func() { <-p.global }()
+		if toolSlot != nil {
+			toolSlot <- struct{}{}
+			defer func() { <-toolSlot }()
+		}
+
+		fn()
+	}()
+}
+
+// wait blocks until every submitted job has completed.
+func (p *workerPool) wait() {
+	p.wg.Wait()
+}